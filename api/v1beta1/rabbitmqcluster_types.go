@@ -0,0 +1,133 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// RabbitmqClusterServiceSpec allows configuration of the ingress Service
+// used to reach the cluster.
+type RabbitmqClusterServiceSpec struct {
+	// Type is the Kubernetes Service type, e.g. ClusterIP, NodePort, LoadBalancer.
+	Type corev1.ServiceType `json:"type,omitempty"`
+}
+
+// HookSpec describes a single deletion-pipeline hook: a container run to
+// completion as a Job before the RabbitmqCluster's finalizer is removed.
+// This lets users plug in graceful queue-drain, definitions-export, or
+// federation-unbind steps without forking the operator.
+type HookSpec struct {
+	// Name identifies the hook and is used to derive its Job's name.
+	Name string `json:"name"`
+	// Image is the container image used to run the hook.
+	Image string `json:"image"`
+	// Command is run in the hook container, e.g. a drain or export script.
+	Command []string `json:"command,omitempty"`
+	// Env is passed through to the hook container in addition to the
+	// variables the controller injects (cluster name, admin credentials
+	// secret, management service DNS, and PVC list).
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// TimeoutSeconds bounds how long the controller waits for this hook's
+	// Job to succeed before failing the finalizer.
+	// +kubebuilder:default:=300
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// PodDisruptionBudgetSpec allows overriding the PodDisruptionBudget the
+// controller manages for HA (Replicas >= 3) clusters. At most one of
+// MinAvailable or MaxUnavailable may be set; the controller defaults to
+// MaxUnavailable: 1 when neither is set.
+type PodDisruptionBudgetSpec struct {
+	// Enabled controls whether the controller manages a PodDisruptionBudget
+	// for this cluster. Defaults to true whenever Replicas >= 3.
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinAvailable and MaxUnavailable are mutually exclusive; at most one
+	// may be set.
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// RabbitmqClusterSpec defines the desired state of a RabbitmqCluster.
+type RabbitmqClusterSpec struct {
+	// Replicas is the number of RabbitMQ nodes in the cluster.
+	// +kubebuilder:default:=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Image is the RabbitMQ image to run.
+	Image string `json:"image,omitempty"`
+
+	// ImagePullSecret is the name of a Secret used to pull Image.
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+
+	// Service defines how the ingress Service is exposed.
+	Service RabbitmqClusterServiceSpec `json:"service,omitempty"`
+
+	// DeletionHooks are Jobs that must run to completion before the
+	// RabbitmqCluster's child resources (StatefulSet, Services, ConfigMaps,
+	// Secrets, PVCs) are allowed to be garbage collected.
+	DeletionHooks []HookSpec `json:"deletionHooks,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget the controller
+	// manages for this cluster.
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// RabbitmqClusterConditionType is a valid value for RabbitmqClusterCondition.Type.
+type RabbitmqClusterConditionType string
+
+// DeletionHookFailed indicates that one of Spec.DeletionHooks did not
+// succeed within its timeout, so the finalizer was not removed.
+const DeletionHookFailed RabbitmqClusterConditionType = "DeletionHookFailed"
+
+// RabbitmqClusterCondition describes the observed state of one aspect of a
+// RabbitmqCluster.
+type RabbitmqClusterCondition struct {
+	Type               RabbitmqClusterConditionType `json:"type"`
+	Status             corev1.ConditionStatus       `json:"status"`
+	Reason             string                       `json:"reason,omitempty"`
+	Message            string                       `json:"message,omitempty"`
+	LastTransitionTime metav1.Time                  `json:"lastTransitionTime,omitempty"`
+}
+
+// RabbitmqClusterStatus defines the observed state of a RabbitmqCluster.
+type RabbitmqClusterStatus struct {
+	// ClusterStatus is a human readable summary of the cluster's state, e.g. "created".
+	ClusterStatus string `json:"clusterStatus,omitempty"`
+	// Conditions holds the latest available observations of the cluster's state.
+	Conditions []RabbitmqClusterCondition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// RabbitmqCluster is the Schema for the rabbitmqclusters API.
+type RabbitmqCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqClusterSpec   `json:"spec,omitempty"`
+	Status RabbitmqClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RabbitmqClusterList contains a list of RabbitmqCluster.
+type RabbitmqClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqCluster{}, &RabbitmqClusterList{})
+}
+
+// ChildResourceName returns the name of a child resource owned by this
+// cluster, e.g. ChildResourceName("server") for the StatefulSet.
+func (c *RabbitmqCluster) ChildResourceName(suffix string) string {
+	return fmt.Sprintf("%s-%s", c.Name, suffix)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rabbitmqv1beta1 "github.com/pivotal/rabbitmq-for-kubernetes/api/v1beta1"
+)
+
+// clusterStatePollInterval bounds how long the cluster-state ConfigMap can
+// go unflipped if the StatefulSet's readiness change is ever missed by the
+// Owns(&appsv1.StatefulSet{}) watch.
+const clusterStatePollInterval = 10 * time.Second
+
+const (
+	clusterStateConfigMapSuffix = "cluster-state"
+	clusterStateKey             = "state"
+
+	// clusterStateNew tells the server entrypoint script to perform normal
+	// bootstrap, including rabbitmqctl force_boot.
+	clusterStateNew = "new"
+
+	// clusterStateExisting tells the server entrypoint script to skip
+	// force_boot/reset logic and wait for peers, so that deleting every pod
+	// at once doesn't cause a split-brain.
+	clusterStateExisting = "existing"
+)
+
+// reconcileClusterStateConfigMap manages the <cluster>-cluster-state
+// ConfigMap mounted by the server pods. It starts out at "new"; once the
+// StatefulSet reports ReadyReplicas == Replicas at least once, it is
+// flipped to "existing" and never flipped back.
+//
+// The flip is driven by the StatefulSet's status, so it's normally caught by
+// the controller's Owns(&appsv1.StatefulSet{}) watch. As a backstop against a
+// missed or coalesced watch event, reconcileClusterStateConfigMap also
+// reports back via ctrl.Result whether the cluster is still waiting on
+// readiness, so Reconcile can requeue it explicitly rather than relying on
+// the watch alone.
+func (r *RabbitmqClusterReconciler) reconcileClusterStateConfigMap(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster) (ctrl.Result, error) {
+	name := cluster.ChildResourceName(clusterStateConfigMapSuffix)
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cluster.Namespace,
+			},
+			Data: map[string]string{clusterStateKey: clusterStateNew},
+		}
+		if err := controllerutil.SetControllerReference(cluster, configMap, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, configMap); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: clusterStatePollInterval}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if configMap.Data[clusterStateKey] == clusterStateExisting {
+		return ctrl.Result{}, nil
+	}
+
+	ready, err := r.statefulSetFullyReady(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		return ctrl.Result{RequeueAfter: clusterStatePollInterval}, nil
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[clusterStateKey] = clusterStateExisting
+		return nil
+	})
+	return ctrl.Result{}, err
+}
+
+func (r *RabbitmqClusterReconciler) statefulSetFullyReady(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster) (bool, error) {
+	sts := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.ChildResourceName("server")}, sts)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sts.Status.ReadyReplicas == cluster.Spec.Replicas, nil
+}
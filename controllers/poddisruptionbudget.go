@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rabbitmqv1beta1 "github.com/pivotal/rabbitmq-for-kubernetes/api/v1beta1"
+)
+
+// errAmbiguousPDBBounds is returned when a user sets both MinAvailable and
+// MaxUnavailable on Spec.PodDisruptionBudget; at most one may be set.
+var errAmbiguousPDBBounds = errors.New("at most one of podDisruptionBudget.minAvailable or podDisruptionBudget.maxUnavailable may be set")
+
+const defaultMaxUnavailable = 1
+
+// reconcilePodDisruptionBudget creates, updates, or deletes the
+// PodDisruptionBudget that protects quorum for HA (Replicas >= 3)
+// RabbitmqClusters, named ChildResourceName("server").
+func (r *RabbitmqClusterReconciler) reconcilePodDisruptionBudget(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster) error {
+	override := cluster.Spec.PodDisruptionBudget
+	if override != nil && override.MinAvailable != nil && override.MaxUnavailable != nil {
+		return errAmbiguousPDBBounds
+	}
+
+	enabled := cluster.Spec.Replicas >= 3
+	if override != nil && override.Enabled != nil {
+		enabled = *override.Enabled
+	}
+
+	name := cluster.ChildResourceName("server")
+
+	if !enabled {
+		existing := &policyv1.PodDisruptionBudget{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, existing)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return r.Delete(ctx, existing)
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		pdb.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"app.kubernetes.io/name": cluster.Name,
+			},
+		}
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = nil
+
+		switch {
+		case override != nil && override.MinAvailable != nil:
+			pdb.Spec.MinAvailable = override.MinAvailable
+		case override != nil && override.MaxUnavailable != nil:
+			pdb.Spec.MaxUnavailable = override.MaxUnavailable
+		default:
+			v := intstr.FromInt(defaultMaxUnavailable)
+			pdb.Spec.MaxUnavailable = &v
+		}
+
+		return controllerutil.SetControllerReference(cluster, pdb, r.Scheme)
+	})
+
+	return err
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rabbitmqv1beta1 "github.com/pivotal/rabbitmq-for-kubernetes/api/v1beta1"
+)
+
+const (
+	// deletionFinalizer is added to every RabbitmqCluster so that the
+	// controller gets a chance to run Spec.DeletionHooks before the
+	// StatefulSet/Service/ConfigMap/Secret/PVCs are garbage collected.
+	deletionFinalizer = "deletion.finalizers.rabbitmq.com"
+
+	// forceDeleteAnnotation lets an operator bypass a stuck or failing
+	// deletion hook and let garbage collection proceed anyway.
+	forceDeleteAnnotation = "rabbitmq.com/force-delete"
+
+	defaultHookTimeout = 300 * time.Second
+)
+
+// RabbitmqClusterReconciler reconciles a RabbitmqCluster object.
+type RabbitmqClusterReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqclusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("rabbitmqcluster", req.NamespacedName)
+
+	cluster := &rabbitmqv1beta1.RabbitmqCluster{}
+	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.reconcileDeletion(ctx, log, cluster)
+	}
+
+	if !containsString(cluster.ObjectMeta.Finalizers, deletionFinalizer) {
+		cluster.ObjectMeta.Finalizers = append(cluster.ObjectMeta.Finalizers, deletionFinalizer)
+		if err := r.Update(ctx, cluster); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.reconcilePodDisruptionBudget(ctx, cluster); err != nil {
+		log.Error(err, "failed to reconcile PodDisruptionBudget")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileServerConfigMap(ctx, cluster); err != nil {
+		log.Error(err, "failed to reconcile server-conf ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.reconcileClusterStateConfigMap(ctx, cluster)
+	if err != nil {
+		log.Error(err, "failed to reconcile cluster-state ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+// reconcileDeletion runs every Spec.DeletionHooks Job to completion before
+// allowing the finalizer (and therefore garbage collection of the cluster's
+// child resources) to be removed. The force-delete annotation is an escape
+// hatch for when a hook is stuck or broken.
+func (r *RabbitmqClusterReconciler) reconcileDeletion(ctx context.Context, log logr.Logger, cluster *rabbitmqv1beta1.RabbitmqCluster) (ctrl.Result, error) {
+	if !containsString(cluster.ObjectMeta.Finalizers, deletionFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if cluster.Annotations[forceDeleteAnnotation] == "true" {
+		log.Info("force-delete annotation present, skipping deletion hooks")
+		return ctrl.Result{}, r.removeFinalizer(ctx, cluster)
+	}
+
+	for _, hook := range cluster.Spec.DeletionHooks {
+		done, err := r.reconcileDeletionHookJob(ctx, cluster, hook)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		switch done {
+		case hookFailed:
+			if err := r.setDeletionHookFailedCondition(ctx, cluster, hook); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		case hookPending:
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+	}
+
+	return ctrl.Result{}, r.removeFinalizer(ctx, cluster)
+}
+
+type hookState int
+
+const (
+	hookSucceeded hookState = iota
+	hookPending
+	hookFailed
+)
+
+// reconcileDeletionHookJob creates the Job for hook if it doesn't exist yet,
+// and reports whether it has succeeded, is still running, or has failed
+// (including exceeding hook.TimeoutSeconds).
+func (r *RabbitmqClusterReconciler) reconcileDeletionHookJob(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster, hook rabbitmqv1beta1.HookSpec) (hookState, error) {
+	job := &batchv1.Job{}
+	name := cluster.ChildResourceName(hook.Name)
+	err := r.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, job)
+	if apierrors.IsNotFound(err) {
+		job = deletionHookJob(cluster, hook)
+		if err := controllerutil.SetControllerReference(cluster, job, r.Scheme); err != nil {
+			return hookPending, err
+		}
+		if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return hookPending, err
+		}
+		return hookPending, nil
+	}
+	if err != nil {
+		return hookPending, err
+	}
+
+	if job.Status.Succeeded > 0 {
+		return hookSucceeded, nil
+	}
+
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	if job.Status.Failed > 0 || time.Since(job.CreationTimestamp.Time) > timeout {
+		return hookFailed, nil
+	}
+
+	return hookPending, nil
+}
+
+func (r *RabbitmqClusterReconciler) setDeletionHookFailedCondition(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster, hook rabbitmqv1beta1.HookSpec) error {
+	upsertCondition(cluster, rabbitmqv1beta1.RabbitmqClusterCondition{
+		Type:               rabbitmqv1beta1.DeletionHookFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             "DeletionHookTimeoutOrFailure",
+		Message:            fmt.Sprintf("deletion hook %q did not succeed within its timeout", hook.Name),
+		LastTransitionTime: metav1.Now(),
+	})
+	return r.Status().Update(ctx, cluster)
+}
+
+// upsertCondition sets condition on cluster's Status.Conditions, replacing
+// any existing condition of the same Type in place rather than appending a
+// duplicate. Without this, every re-reconcile of a failing deletion hook
+// would grow Status.Conditions without bound.
+func upsertCondition(cluster *rabbitmqv1beta1.RabbitmqCluster, condition rabbitmqv1beta1.RabbitmqClusterCondition) {
+	for i, existing := range cluster.Status.Conditions {
+		if existing.Type == condition.Type {
+			cluster.Status.Conditions[i] = condition
+			return
+		}
+	}
+	cluster.Status.Conditions = append(cluster.Status.Conditions, condition)
+}
+
+func (r *RabbitmqClusterReconciler) removeFinalizer(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster) error {
+	cluster.ObjectMeta.Finalizers = removeString(cluster.ObjectMeta.Finalizers, deletionFinalizer)
+	return r.Update(ctx, cluster)
+}
+
+// deletionHookJob builds the Job for hook, pre-populating env vars for the
+// cluster name, admin credentials secret, management service DNS, and PVC list.
+func deletionHookJob(cluster *rabbitmqv1beta1.RabbitmqCluster, hook rabbitmqv1beta1.HookSpec) *batchv1.Job {
+	backoffLimit := int32(0)
+	env := append([]corev1.EnvVar{
+		{Name: "RABBITMQ_CLUSTER_NAME", Value: cluster.Name},
+		{Name: "RABBITMQ_ADMIN_SECRET", Value: cluster.ChildResourceName("default-user")},
+		{Name: "RABBITMQ_MANAGEMENT_SERVICE", Value: fmt.Sprintf("%s.%s.svc", cluster.ChildResourceName("ingress"), cluster.Namespace)},
+		{Name: "RABBITMQ_PVC_LIST", Value: pvcListEnvValue(cluster)},
+	}, hook.Env...)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.ChildResourceName(hook.Name),
+			Namespace: cluster.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    hook.Name,
+							Image:   hook.Image,
+							Command: hook.Command,
+							Env:     env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func pvcListEnvValue(cluster *rabbitmqv1beta1.RabbitmqCluster) string {
+	value := ""
+	for i := int32(0); i < cluster.Spec.Replicas; i++ {
+		if i > 0 {
+			value += ","
+		}
+		value += fmt.Sprintf("persistence-%s-%d", cluster.ChildResourceName("server"), i)
+	}
+	return value
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(slice []string, s string) []string {
+	result := make([]string, 0, len(slice))
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (r *RabbitmqClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rabbitmqv1beta1.RabbitmqCluster{}).
+		Owns(&batchv1.Job{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
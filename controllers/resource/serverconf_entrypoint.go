@@ -0,0 +1,24 @@
+// Package resource holds string templates used to build the content of
+// RabbitmqCluster child ConfigMaps.
+package resource
+
+// ServerEntrypointScript is mounted into the server-conf ConfigMap as
+// entrypoint.sh. It branches on the cluster-state ConfigMap's "state" key:
+// "new" clusters bootstrap normally, while "existing" clusters skip
+// force_boot/reset and wait for peers, so that restarting every pod at once
+// can't cause a split-brain.
+const ServerEntrypointScript = `#!/bin/sh
+set -eu
+
+CLUSTER_STATE_FILE=/etc/rabbitmq-cluster-state/state
+CLUSTER_STATE=$(cat "$CLUSTER_STATE_FILE" 2>/dev/null || echo new)
+
+if [ "$CLUSTER_STATE" = "existing" ]; then
+  echo "cluster-state is 'existing': skipping force_boot/reset, waiting for peers"
+else
+  echo "cluster-state is 'new': performing normal bootstrap"
+  rabbitmqctl force_boot || true
+fi
+
+exec rabbitmq-server
+`
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rabbitmqv1beta1 "github.com/pivotal/rabbitmq-for-kubernetes/api/v1beta1"
+	"github.com/pivotal/rabbitmq-for-kubernetes/controllers/resource"
+)
+
+const (
+	serverConfigMapSuffix = "server-conf"
+	entrypointScriptKey   = "entrypoint.sh"
+)
+
+// reconcileServerConfigMap manages the <cluster>-server-conf ConfigMap mounted
+// into the server pods as entrypoint.sh, keeping its content in sync with
+// resource.ServerEntrypointScript.
+func (r *RabbitmqClusterReconciler) reconcileServerConfigMap(ctx context.Context, cluster *rabbitmqv1beta1.RabbitmqCluster) error {
+	name := cluster.ChildResourceName(serverConfigMapSuffix)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[entrypointScriptKey] = resource.ServerEntrypointScript
+		return controllerutil.SetControllerReference(cluster, configMap, r.Scheme)
+	})
+	return err
+}
@@ -0,0 +1,56 @@
+package system_tests
+
+import (
+	"time"
+
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// DefaultTimeout bounds every retried Kubernetes call made through the
+	// helpers in this file.
+	DefaultTimeout = 5 * time.Second
+	// PollingInterval is how often a retried call is re-attempted within DefaultTimeout.
+	PollingInterval = 250 * time.Millisecond
+)
+
+// GetK8sObjectWithRetry retries getFn, which is typically a typed client's
+// Get method value (e.g. clientSet.CoreV1().Pods(ns).Get), until it succeeds
+// or DefaultTimeout elapses. A "not found" error is treated as fatal rather
+// than transient, since the object genuinely isn't there.
+func GetK8sObjectWithRetry[T any](getFn func(name string, opts metav1.GetOptions) (*T, error), name string, opts metav1.GetOptions) *T {
+	var obj *T
+	Eventually(func() error {
+		o, err := getFn(name, opts)
+		if k8serrors.IsNotFound(err) {
+			StopTrying(err.Error()).Now()
+		}
+		if err != nil {
+			return err
+		}
+		obj = o
+		return nil
+	}, DefaultTimeout, PollingInterval).Should(Succeed())
+	return obj
+}
+
+// DeleteK8sObjectWithRetry retries deleteFn, typically a typed client's
+// Delete method value, until it succeeds, the object is already gone, or
+// DefaultTimeout elapses.
+func DeleteK8sObjectWithRetry(deleteFn func(name string, opts *metav1.DeleteOptions) error, name string, opts *metav1.DeleteOptions) {
+	Eventually(func() error {
+		err := deleteFn(name, opts)
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}, DefaultTimeout, PollingInterval).Should(Succeed())
+}
+
+// IgnoreNotFound is a predicate suitable for Eventually(...).Should(Satisfy(IgnoreNotFound)),
+// letting callers poll a Get until the object has disappeared.
+func IgnoreNotFound(err error) bool {
+	return k8serrors.IsNotFound(err)
+}
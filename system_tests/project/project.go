@@ -0,0 +1,48 @@
+// Package project provides helpers for standing up and tearing down the
+// per-spec namespace that each system test runs in, so that specs can be
+// run in parallel (ginkgo -p) without leaking state into one another.
+package project
+
+import (
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const readinessTimeout = 30 * time.Second
+
+// CreateNamespace creates a Namespace called name and waits for it to become Active.
+func CreateNamespace(clientSet *kubernetes.Clientset, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if _, err := clientSet.CoreV1().Namespaces().Create(ns); err != nil {
+		return err
+	}
+
+	Eventually(func() corev1.NamespacePhase {
+		got, err := clientSet.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return got.Status.Phase
+	}, readinessTimeout).Should(Equal(corev1.NamespaceActive))
+
+	return nil
+}
+
+// DeleteNamespace deletes the Namespace called name and waits for it to be gone.
+func DeleteNamespace(clientSet *kubernetes.Clientset, name string) error {
+	if err := clientSet.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+
+	Eventually(func() bool {
+		_, err := clientSet.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		return k8serrors.IsNotFound(err)
+	}, readinessTimeout).Should(BeTrue())
+
+	return nil
+}
@@ -3,6 +3,8 @@ package system_tests
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"sync"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
@@ -11,27 +13,39 @@ import (
 	. "github.com/onsi/gomega"
 
 	rabbitmqv1beta1 "github.com/pivotal/rabbitmq-for-kubernetes/api/v1beta1"
+	"github.com/pivotal/rabbitmq-for-kubernetes/system_tests/project"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
-	podCreationTimeout     = 360 * time.Second
-	serviceCreationTimeout = 10 * time.Second
-	ingressServiceSuffix   = "ingress"
-	statefulSetSuffix      = "server"
-	configMapSuffix        = "server-conf"
+	podCreationTimeout          = 360 * time.Second
+	serviceCreationTimeout      = 10 * time.Second
+	ingressServiceSuffix        = "ingress"
+	statefulSetSuffix           = "server"
+	configMapSuffix             = "server-conf"
+	clusterStateConfigMapSuffix = "cluster-state"
 )
 
 var _ = Describe("Operator", func() {
 	var (
 		clientSet *kubernetes.Clientset
-		namespace = MustHaveEnv("NAMESPACE")
+		namespace string
 	)
 
 	BeforeEach(func() {
 		var err error
 		clientSet, err = createClientSet()
 		Expect(err).NotTo(HaveOccurred())
+
+		namespace = fmt.Sprintf("test-e2e-%d-%d", GinkgoParallelNode(), rand.Int31())
+		Expect(project.CreateNamespace(clientSet, namespace)).To(Succeed())
+		Expect(deployOperator(namespace)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(undeployOperator(namespace)).To(Succeed())
+		Expect(project.DeleteNamespace(clientSet, namespace)).To(Succeed())
 	})
 
 	Context("Initial RabbitmqCluster setup", func() {
@@ -94,7 +108,7 @@ var _ = Describe("Operator", func() {
 			})
 
 			By("updating the CR status correctly", func() {
-				Expect(clientSet.CoreV1().Pods(namespace).Delete(statefulSetPodName(cluster, 0), &metav1.DeleteOptions{})).NotTo(HaveOccurred())
+				DeleteK8sObjectWithRetry(clientSet.CoreV1().Pods(namespace).Delete, statefulSetPodName(cluster, 0), &metav1.DeleteOptions{})
 
 				Eventually(func() []byte {
 					output, err := kubectl(
@@ -137,8 +151,7 @@ var _ = Describe("Operator", func() {
 
 			By("setting owner reference to persistence volume claim successfully", func() {
 				pvcName := "persistence-" + statefulSetPodName(cluster, 0)
-				pvc, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(pvcName, metav1.GetOptions{})
-				Expect(err).NotTo(HaveOccurred())
+				pvc := GetK8sObjectWithRetry(clientSet.CoreV1().PersistentVolumeClaims(namespace).Get, pvcName, metav1.GetOptions{})
 				Expect(len(pvc.OwnerReferences)).To(Equal(1))
 				Expect(pvc.OwnerReferences[0].Name).To(Equal(cluster.Name))
 			})
@@ -151,14 +164,17 @@ var _ = Describe("Operator", func() {
 			configMapName string
 			serviceName   string
 			stsName       string
+			pdbName       string
 		)
 
 		BeforeEach(func() {
 			cluster = generateRabbitmqCluster(namespace, "delete-my-resources")
+			cluster.Spec.Replicas = 3
 
 			configMapName = cluster.ChildResourceName(configMapSuffix)
 			serviceName = cluster.ChildResourceName(ingressServiceSuffix)
 			stsName = cluster.ChildResourceName(statefulSetSuffix)
+			pdbName = cluster.ChildResourceName(statefulSetSuffix)
 			Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
 
 			waitForRabbitmqRunning(cluster)
@@ -172,18 +188,18 @@ var _ = Describe("Operator", func() {
 		})
 
 		It("recreates the resources", func() {
-			oldConfMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(configMapName, metav1.GetOptions{})
-			Expect(err).NotTo(HaveOccurred())
+			oldConfMap := GetK8sObjectWithRetry(clientSet.CoreV1().ConfigMaps(namespace).Get, configMapName, metav1.GetOptions{})
 
-			oldIngressSvc, err := clientSet.CoreV1().Services(namespace).Get(serviceName, metav1.GetOptions{})
-			Expect(err).NotTo(HaveOccurred())
+			oldIngressSvc := GetK8sObjectWithRetry(clientSet.CoreV1().Services(namespace).Get, serviceName, metav1.GetOptions{})
 
-			oldSts, err := clientSet.AppsV1().StatefulSets(namespace).Get(stsName, metav1.GetOptions{})
-			Expect(err).NotTo(HaveOccurred())
+			oldSts := GetK8sObjectWithRetry(clientSet.AppsV1().StatefulSets(namespace).Get, stsName, metav1.GetOptions{})
+
+			oldPdb := GetK8sObjectWithRetry(clientSet.PolicyV1().PodDisruptionBudgets(namespace).Get, pdbName, metav1.GetOptions{})
 
-			Expect(clientSet.AppsV1().StatefulSets(namespace).Delete(stsName, &metav1.DeleteOptions{})).NotTo(HaveOccurred())
-			Expect(clientSet.CoreV1().ConfigMaps(namespace).Delete(configMapName, &metav1.DeleteOptions{})).NotTo(HaveOccurred())
-			Expect(clientSet.CoreV1().Services(namespace).Delete(serviceName, &metav1.DeleteOptions{})).NotTo(HaveOccurred())
+			DeleteK8sObjectWithRetry(clientSet.AppsV1().StatefulSets(namespace).Delete, stsName, &metav1.DeleteOptions{})
+			DeleteK8sObjectWithRetry(clientSet.CoreV1().ConfigMaps(namespace).Delete, configMapName, &metav1.DeleteOptions{})
+			DeleteK8sObjectWithRetry(clientSet.CoreV1().Services(namespace).Delete, serviceName, &metav1.DeleteOptions{})
+			DeleteK8sObjectWithRetry(clientSet.PolicyV1().PodDisruptionBudgets(namespace).Delete, pdbName, &metav1.DeleteOptions{})
 
 			Eventually(func() string {
 				confMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(configMapName, metav1.GetOptions{})
@@ -209,10 +225,175 @@ var _ = Describe("Operator", func() {
 				return string(sts.UID)
 			}, 10).Should(Not(Equal(oldSts.UID)))
 
+			Eventually(func() string {
+				pdb, err := clientSet.PolicyV1().PodDisruptionBudgets(namespace).Get(pdbName, metav1.GetOptions{})
+				if err != nil {
+					return err.Error()
+				}
+				return string(pdb.UID)
+			}, 10).Should(Not(Equal(oldPdb.UID)))
+
 			assertStatefulSetReady(cluster)
 		})
 	})
 
+	Context("Deletion hooks", func() {
+		var cluster *rabbitmqv1beta1.RabbitmqCluster
+
+		BeforeEach(func() {
+			cluster = generateRabbitmqCluster(namespace, "deletion-hooks-rabbit")
+		})
+
+		AfterEach(func() {
+			err := rmqClusterClient.Delete(context.TODO(), cluster)
+			if err != nil {
+				Expect(err).To(MatchError("not found"))
+			}
+		})
+
+		When("all deletion hooks succeed", func() {
+			BeforeEach(func() {
+				cluster.Spec.DeletionHooks = []rabbitmqv1beta1.HookSpec{
+					{
+						Name:           "drain-queues",
+						Image:          "busybox:latest",
+						Command:        []string{"sh", "-c", "exit 0"},
+						TimeoutSeconds: 30,
+					},
+				}
+				Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
+				waitForRabbitmqRunning(cluster)
+			})
+
+			It("runs the hook Jobs to completion before the finalizer is removed", func() {
+				Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+
+				Eventually(func() int32 {
+					job, err := clientSet.BatchV1().Jobs(namespace).Get(cluster.ChildResourceName("drain-queues"), metav1.GetOptions{})
+					if err != nil {
+						return 0
+					}
+					return job.Status.Succeeded
+				}, podCreationTimeout).Should(BeEquivalentTo(1))
+
+				Eventually(func() bool {
+					output, err := kubectl("-n", cluster.Namespace, "get", "rabbitmqclusters", cluster.Name)
+					return err == nil && string(output) != ""
+				}, podCreationTimeout).Should(BeFalse())
+			})
+		})
+
+		When("a deletion hook exceeds its timeout", func() {
+			BeforeEach(func() {
+				cluster.Spec.DeletionHooks = []rabbitmqv1beta1.HookSpec{
+					{
+						Name:           "stuck-export",
+						Image:          "busybox:latest",
+						Command:        []string{"sh", "-c", "sleep 600"},
+						TimeoutSeconds: 5,
+					},
+				}
+				Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
+				waitForRabbitmqRunning(cluster)
+			})
+
+			It("fails the finalizer with a status condition and leaves the cluster resources in place", func() {
+				Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+
+				Eventually(func() []byte {
+					output, err := kubectl(
+						"-n",
+						cluster.Namespace,
+						"get",
+						"rabbitmqclusters",
+						cluster.Name,
+						"-o=jsonpath='{.status.conditions[?(@.type==\"DeletionHookFailed\")].status}'",
+					)
+					Expect(err).NotTo(HaveOccurred())
+					return output
+				}, podCreationTimeout).Should(ContainSubstring("True"))
+
+				GetK8sObjectWithRetry(clientSet.AppsV1().StatefulSets(namespace).Get, cluster.ChildResourceName(statefulSetSuffix), metav1.GetOptions{})
+			})
+		})
+
+		When("a deletion hook fails but the force-delete annotation is present", func() {
+			BeforeEach(func() {
+				cluster.Annotations = map[string]string{"rabbitmq.com/force-delete": "true"}
+				cluster.Spec.DeletionHooks = []rabbitmqv1beta1.HookSpec{
+					{
+						Name:           "broken-hook",
+						Image:          "busybox:latest",
+						Command:        []string{"sh", "-c", "exit 1"},
+						TimeoutSeconds: 30,
+					},
+				}
+				Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
+				waitForRabbitmqRunning(cluster)
+			})
+
+			It("skips waiting on the failed hook and removes the finalizer anyway", func() {
+				Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+
+				Eventually(func() bool {
+					output, err := kubectl("-n", cluster.Namespace, "get", "rabbitmqclusters", cluster.Name)
+					return err == nil && string(output) != ""
+				}, podCreationTimeout).Should(BeFalse())
+			})
+		})
+	})
+
+	Context("PodDisruptionBudget overrides", func() {
+		var cluster *rabbitmqv1beta1.RabbitmqCluster
+
+		BeforeEach(func() {
+			cluster = generateRabbitmqCluster(namespace, "pdb-override-rabbit")
+			cluster.Spec.Replicas = 3
+		})
+
+		AfterEach(func() {
+			Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+		})
+
+		When("MinAvailable is set instead of the default MaxUnavailable", func() {
+			BeforeEach(func() {
+				minAvailable := intstr.FromInt(2)
+				cluster.Spec.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudgetSpec{
+					MinAvailable: &minAvailable,
+				}
+				Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
+				waitForRabbitmqRunning(cluster)
+			})
+
+			It("honors the override instead of defaulting MaxUnavailable", func() {
+				pdb := GetK8sObjectWithRetry(clientSet.PolicyV1().PodDisruptionBudgets(namespace).Get, cluster.ChildResourceName(statefulSetSuffix), metav1.GetOptions{})
+
+				Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+				Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(2))
+			})
+		})
+
+		When("both MinAvailable and MaxUnavailable are set", func() {
+			BeforeEach(func() {
+				minAvailable := intstr.FromInt(2)
+				maxUnavailable := intstr.FromInt(1)
+				cluster.Spec.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudgetSpec{
+					MinAvailable:   &minAvailable,
+					MaxUnavailable: &maxUnavailable,
+				}
+				Expect(createRabbitmqCluster(rmqClusterClient, cluster)).NotTo(HaveOccurred())
+				waitForRabbitmqRunning(cluster)
+			})
+
+			It("rejects the ambiguous spec and never creates a PodDisruptionBudget", func() {
+				Consistently(func() bool {
+					_, err := clientSet.PolicyV1().PodDisruptionBudgets(namespace).Get(cluster.ChildResourceName(statefulSetSuffix), metav1.GetOptions{})
+					return IgnoreNotFound(err)
+				}, serviceCreationTimeout).Should(BeTrue())
+			})
+		})
+	})
+
 	Context("Clustering", func() {
 		When("RabbitmqCluster is deployed with 3 nodes", func() {
 			var cluster *rabbitmqv1beta1.RabbitmqCluster
@@ -238,6 +419,100 @@ var _ = Describe("Operator", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(response.Status).To(Equal("ok"))
 			})
+
+			It("creates a PodDisruptionBudget protecting quorum", func() {
+				pdb := GetK8sObjectWithRetry(clientSet.PolicyV1().PodDisruptionBudgets(namespace).Get, cluster.ChildResourceName(statefulSetSuffix), metav1.GetOptions{})
+
+				Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+				Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{
+					"app.kubernetes.io/name": cluster.Name,
+				}))
+			})
+
+			It("rejoins the cluster without split-brain after all pods are deleted concurrently", func() {
+				waitForRabbitmqRunning(cluster)
+				username, password, err := getRabbitmqUsernameAndPassword(clientSet, cluster.Namespace, cluster.Name)
+				hostname := rabbitmqHostname(clientSet, cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rabbitmqPublishToNewQueue(hostname, username, password)).NotTo(HaveOccurred())
+
+				Eventually(func() string {
+					confMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(cluster.ChildResourceName(clusterStateConfigMapSuffix), metav1.GetOptions{})
+					if err != nil {
+						return err.Error()
+					}
+					return confMap.Data["state"]
+				}, podCreationTimeout).Should(Equal("existing"))
+
+				By("deleting every server pod at the same time", func() {
+					var wg sync.WaitGroup
+					for i := 0; i < 3; i++ {
+						wg.Add(1)
+						go func(i int) {
+							defer wg.Done()
+							defer GinkgoRecover()
+							DeleteK8sObjectWithRetry(clientSet.CoreV1().Pods(namespace).Delete, statefulSetPodName(cluster, i), &metav1.DeleteOptions{})
+						}(i)
+					}
+					wg.Wait()
+				})
+
+				waitForRabbitmqRunning(cluster)
+
+				Eventually(func() string {
+					confMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(cluster.ChildResourceName(clusterStateConfigMapSuffix), metav1.GetOptions{})
+					if err != nil {
+						return err.Error()
+					}
+					return confMap.Data["state"]
+				}, podCreationTimeout).Should(Equal("existing"))
+
+				message, err := rabbitmqGetMessageFromQueue(hostname, username, password)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(message.Payload).To(Equal("hello"))
+			})
 		})
 	})
 })
+
+// deployClusterScopedResources installs the cluster-scoped pieces of the
+// operator (the RabbitmqCluster CRD and its ClusterRole/ClusterRoleBinding)
+// once for the whole suite. These are singletons: no spec may create or
+// delete them, since specs run in parallel under ginkgo -p.
+func deployClusterScopedResources() error {
+	if _, err := kubectl("apply", "-k", "../config/crd"); err != nil {
+		return err
+	}
+	_, err := kubectl("apply", "-k", "../config/rbac")
+	return err
+}
+
+// undeployClusterScopedResources removes what deployClusterScopedResources installed.
+func undeployClusterScopedResources() error {
+	if _, err := kubectl("delete", "-k", "../config/rbac", "--ignore-not-found"); err != nil {
+		return err
+	}
+	_, err := kubectl("delete", "-k", "../config/crd", "--ignore-not-found")
+	return err
+}
+
+// deployOperator deploys a namespace-scoped operator Deployment watching
+// only namespace, so that each spec gets its own controller lifecycle and
+// specs can run with ginkgo -p without fighting over a shared manager.
+func deployOperator(namespace string) error {
+	if _, err := kubectl("apply", "--namespace", namespace, "-k", "../config/manager"); err != nil {
+		return err
+	}
+	_, err := kubectl("set", "env", "--namespace", namespace, "deployment/rabbitmq-cluster-operator",
+		fmt.Sprintf("WATCH_NAMESPACE=%s", namespace))
+	return err
+}
+
+// undeployOperator tears down the namespace-scoped Deployment created by
+// deployOperator. It never touches the cluster-scoped CRD/RBAC installed by
+// deployClusterScopedResources, since other specs' operators may still be
+// running against it.
+func undeployOperator(namespace string) error {
+	_, err := kubectl("delete", "--namespace", namespace, "-k", "../config/manager", "--ignore-not-found")
+	return err
+}
@@ -0,0 +1,25 @@
+package system_tests
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSystemTests(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "System Tests Suite")
+}
+
+// The RabbitmqCluster CRD and its RBAC are cluster-scoped, so they're
+// installed once for the whole suite rather than per spec; per-spec
+// namespaces and operator Deployments (see deployOperator) are what keep
+// parallel specs from contaminating each other.
+var _ = BeforeSuite(func() {
+	Expect(deployClusterScopedResources()).To(Succeed())
+})
+
+var _ = AfterSuite(func() {
+	Expect(undeployClusterScopedResources()).To(Succeed())
+})